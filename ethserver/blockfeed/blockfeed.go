@@ -0,0 +1,298 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package blockfeed listens for committed blocks on a Fabric channel and
+// translates them into the synthetic Ethereum headers and logs that the
+// filter/subscription APIs are built on.
+package blockfeed
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+)
+
+// maxHistory bounds how many recent blocks are kept in memory for filters
+// to poll against; older blocks are only available via qscc directly.
+const maxHistory = 256
+
+// EventHub is the subset of the Fabric SDK's channel event service the
+// feed needs. It is satisfied by the SDK's real event hub, and lets the
+// feed be exercised with a fake in tests.
+type EventHub interface {
+	RegisterBlockEvent() (chan *common.Block, func(), error)
+}
+
+// Record pairs a synthetic header with the logs emitted by evmscc in
+// that block.
+type Record struct {
+	Header ethserver.Header
+	Logs   []ethserver.Log
+	TxIDs  []string
+}
+
+// Feed fans out committed-block data to newHeads/logs/pending-transaction
+// subscribers and keeps a bounded history so filters can poll for
+// changes since their last check.
+type Feed struct {
+	evmscc string
+
+	mu       sync.RWMutex
+	history  []Record
+	baseSeq  uint64 // absolute sequence number of history[0]
+	totalSeq uint64 // absolute sequence number of the next block to record
+
+	subMu    sync.Mutex
+	headSubs map[int]chan ethserver.Header
+	logSubs  map[int]chan ethserver.Log
+	pendSubs map[int]chan string
+	nextSub  int
+}
+
+func New(cfg ethserver.Config) *Feed {
+	return &Feed{
+		evmscc:   cfg.EVMSCC,
+		headSubs: map[int]chan ethserver.Header{},
+		logSubs:  map[int]chan ethserver.Log{},
+		pendSubs: map[int]chan string{},
+	}
+}
+
+// Listen registers with the event hub and processes blocks in the
+// background until the hub closes the block channel, at which point the
+// registration is undone. It returns an error only if registering fails.
+func (f *Feed) Listen(hub EventHub) error {
+	blocks, unregister, err := hub.RegisterBlockEvent()
+	if err != nil {
+		return fmt.Errorf("failed to register block event: %s", err)
+	}
+
+	go func() {
+		defer unregister()
+		for block := range blocks {
+			f.onBlock(block)
+		}
+	}()
+
+	return nil
+}
+
+func (f *Feed) onBlock(block *common.Block) {
+	header := ethserver.Header{
+		Number:     "0x" + strconv.FormatUint(block.GetHeader().GetNumber(), 16),
+		Hash:       "0x" + hex.EncodeToString(block.GetHeader().Hash()),
+		ParentHash: "0x" + hex.EncodeToString(block.GetHeader().GetPreviousHash()),
+	}
+
+	logs, txIDs := f.extractLogs(block, header)
+
+	f.mu.Lock()
+	f.history = append(f.history, Record{Header: header, Logs: logs, TxIDs: txIDs})
+	f.totalSeq++
+	if len(f.history) > maxHistory {
+		trimmed := len(f.history) - maxHistory
+		f.history = f.history[trimmed:]
+		f.baseSeq += uint64(trimmed)
+	}
+	f.mu.Unlock()
+
+	f.publish(header, logs, txIDs)
+}
+
+// extractLogs walks each transaction action in the block looking for
+// chaincode events emitted by evmscc, and decodes them into EVM logs.
+// evmscc emits its logs as a JSON-encoded []ethserver.Log in the
+// chaincode event payload.
+func (f *Feed) extractLogs(block *common.Block, header ethserver.Header) ([]ethserver.Log, []string) {
+	var logs []ethserver.Log
+	var txIDs []string
+
+	for txIndex, envelopeBytes := range block.GetData().GetData() {
+		envelope := &common.Envelope{}
+		if err := proto.Unmarshal(envelopeBytes, envelope); err != nil {
+			continue
+		}
+
+		payload := &common.Payload{}
+		if err := proto.Unmarshal(envelope.GetPayload(), payload); err != nil {
+			continue
+		}
+
+		tx := &peer.Transaction{}
+		if err := proto.Unmarshal(payload.GetData(), tx); err != nil {
+			continue
+		}
+
+		for _, action := range tx.GetActions() {
+			_, respPayload, err := ethserver.GetPayloads(action)
+			if err != nil || respPayload == nil {
+				continue
+			}
+
+			ccEvent := &peer.ChaincodeEvent{}
+			if err := proto.Unmarshal(respPayload.GetEvents(), ccEvent); err != nil {
+				continue
+			}
+
+			if ccEvent.GetChaincodeId() != f.evmscc {
+				continue
+			}
+
+			txIDs = append(txIDs, ccEvent.GetTxId())
+
+			var eventLogs []ethserver.Log
+			if err := json.Unmarshal(ccEvent.GetPayload(), &eventLogs); err != nil {
+				continue
+			}
+
+			for i := range eventLogs {
+				eventLogs[i].BlockNumber = header.Number
+				eventLogs[i].BlockHash = header.Hash
+				eventLogs[i].TransactionHash = "0x" + ccEvent.GetTxId()
+				eventLogs[i].TransactionIndex = "0x" + strconv.Itoa(txIndex)
+				eventLogs[i].LogIndex = "0x" + strconv.Itoa(len(logs)+i)
+			}
+			logs = append(logs, eventLogs...)
+		}
+	}
+
+	return logs, txIDs
+}
+
+func (f *Feed) publish(header ethserver.Header, logs []ethserver.Log, txIDs []string) {
+	f.subMu.Lock()
+	defer f.subMu.Unlock()
+
+	for _, ch := range f.headSubs {
+		select {
+		case ch <- header:
+		default:
+		}
+	}
+	for _, log := range logs {
+		for _, ch := range f.logSubs {
+			select {
+			case ch <- log:
+			default:
+			}
+		}
+	}
+	for _, txID := range txIDs {
+		for _, ch := range f.pendSubs {
+			select {
+			case ch <- txID:
+			default:
+			}
+		}
+	}
+}
+
+// History returns the recorded blocks, in order, oldest first.
+func (f *Feed) History() []Record {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	history := make([]Record, len(f.history))
+	copy(history, f.history)
+	return history
+}
+
+// CurrentSeq returns the absolute sequence number of the next block the
+// feed will record. Callers that want to start tracking from "now" (e.g.
+// a freshly registered filter) use this as their initial cursor.
+func (f *Feed) CurrentSeq() uint64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.totalSeq
+}
+
+// Since returns every recorded block with an absolute sequence number >=
+// cursor, along with the cursor a caller should pass on its next call to
+// observe only what comes after. Because history is bounded to
+// maxHistory entries, a cursor older than the oldest retained block
+// yields whatever is still available rather than panicking or
+// misreporting - the caller has unavoidably missed the trimmed blocks.
+func (f *Feed) Since(cursor uint64) ([]Record, uint64) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	start := 0
+	if cursor > f.baseSeq {
+		start = int(cursor - f.baseSeq)
+		if start > len(f.history) {
+			start = len(f.history)
+		}
+	}
+
+	records := make([]Record, len(f.history)-start)
+	copy(records, f.history[start:])
+	return records, f.totalSeq
+}
+
+// SubscribeHeads registers a new newHeads subscriber and returns a
+// channel of headers plus a func to tear the subscription down.
+func (f *Feed) SubscribeHeads() (<-chan ethserver.Header, func()) {
+	f.subMu.Lock()
+	defer f.subMu.Unlock()
+
+	id := f.nextSub
+	f.nextSub++
+	ch := make(chan ethserver.Header, 16)
+	f.headSubs[id] = ch
+
+	return ch, func() {
+		f.subMu.Lock()
+		defer f.subMu.Unlock()
+		delete(f.headSubs, id)
+		close(ch)
+	}
+}
+
+// SubscribeLogs registers a new logs subscriber.
+func (f *Feed) SubscribeLogs() (<-chan ethserver.Log, func()) {
+	f.subMu.Lock()
+	defer f.subMu.Unlock()
+
+	id := f.nextSub
+	f.nextSub++
+	ch := make(chan ethserver.Log, 64)
+	f.logSubs[id] = ch
+
+	return ch, func() {
+		f.subMu.Lock()
+		defer f.subMu.Unlock()
+		delete(f.logSubs, id)
+		close(ch)
+	}
+}
+
+// SubscribePendingTransactions registers a new pending-transaction
+// subscriber. Fabric endorses and commits in one flow, so "pending" here
+// really means "just committed" - there is no separate mempool to watch.
+func (f *Feed) SubscribePendingTransactions() (<-chan string, func()) {
+	f.subMu.Lock()
+	defer f.subMu.Unlock()
+
+	id := f.nextSub
+	f.nextSub++
+	ch := make(chan string, 64)
+	f.pendSubs[id] = ch
+
+	return ch, func() {
+		f.subMu.Lock()
+		defer f.subMu.Unlock()
+		delete(f.pendSubs, id)
+		close(ch)
+	}
+}
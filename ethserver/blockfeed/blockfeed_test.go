@@ -0,0 +1,86 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockfeed
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/protos/common"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+)
+
+func recordBlocks(f *Feed, n int) {
+	for i := 0; i < n; i++ {
+		f.onBlock(&common.Block{Header: &common.BlockHeader{Number: uint64(i)}})
+	}
+}
+
+func TestSinceReturnsOnlyWhatsNew(t *testing.T) {
+	f := New(ethserver.Config{})
+	recordBlocks(f, 5)
+
+	if seq := f.CurrentSeq(); seq != 5 {
+		t.Fatalf("CurrentSeq() = %d, want 5", seq)
+	}
+
+	records, next := f.Since(2)
+	if len(records) != 3 {
+		t.Fatalf("Since(2) returned %d records, want 3", len(records))
+	}
+	if next != 5 {
+		t.Fatalf("Since(2) next cursor = %d, want 5", next)
+	}
+}
+
+// TestSinceSurvivesHistoryTrim reproduces the scenario a filter cursor
+// must handle correctly: the cursor was captured while history was
+// short, and many more blocks have since pushed it past maxHistory. The
+// cursor is an absolute sequence number, so this must not panic or
+// silently renumber the retained window.
+func TestSinceSurvivesHistoryTrim(t *testing.T) {
+	f := New(ethserver.Config{})
+
+	cursor := f.CurrentSeq() // captured after 0 blocks, like a freshly registered filter
+	recordBlocks(f, 5)
+	cursorAfterFive := f.CurrentSeq()
+
+	recordBlocks(f, maxHistory+25)
+
+	// The early cursor predates everything still retained; it should
+	// yield the whole trimmed window rather than panicking.
+	records, next := f.Since(cursor)
+	if len(records) != maxHistory {
+		t.Fatalf("Since(%d) returned %d records, want the full retained window of %d", cursor, len(records), maxHistory)
+	}
+	if next != uint64(5+maxHistory+25) {
+		t.Fatalf("Since(%d) next cursor = %d, want %d", cursor, next, 5+maxHistory+25)
+	}
+
+	// A cursor captured partway through should still resolve correctly
+	// once it too has aged out of the window.
+	records, next = f.Since(cursorAfterFive)
+	if len(records) != maxHistory {
+		t.Fatalf("Since(%d) returned %d records, want %d", cursorAfterFive, len(records), maxHistory)
+	}
+	if next != uint64(5+maxHistory+25) {
+		t.Fatalf("Since(%d) next cursor = %d, want %d", cursorAfterFive, next, 5+maxHistory+25)
+	}
+}
+
+func TestSinceWithFutureCursorReturnsNothing(t *testing.T) {
+	f := New(ethserver.Config{})
+	recordBlocks(f, 3)
+
+	records, next := f.Since(f.CurrentSeq())
+	if len(records) != 0 {
+		t.Fatalf("Since(CurrentSeq()) returned %d records, want 0", len(records))
+	}
+	if next != 3 {
+		t.Fatalf("next cursor = %d, want 3", next)
+	}
+}
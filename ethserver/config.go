@@ -0,0 +1,57 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ethserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLSConfig holds the material needed to serve the RPC endpoint over
+// HTTPS. Setting ClientCAFile additionally requires clients to present a
+// certificate signed by that CA (mTLS).
+type TLSConfig struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+func (t *TLSConfig) tlsConfig() (*tls.Config, error) {
+	if t.ClientCAFile == "" {
+		return &tls.Config{}, nil
+	}
+
+	caCert, err := ioutil.ReadFile(t.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %s", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to parse client CA file: %s", t.ClientCAFile)
+	}
+
+	return &tls.Config{
+		ClientCAs:  pool,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+	}, nil
+}
+
+// Config carries the per-deployment settings that used to be hardcoded in
+// this package: which channel and system chaincodes to query, which
+// origins browser dapps may call in from, and how to listen.
+type Config struct {
+	ChannelID      string
+	EVMSCC         string
+	QSCC           string
+	AllowedOrigins []string
+	ListenAddr     string
+	// TLS enables HTTPS (and optionally mTLS) when set; nil serves plain HTTP.
+	TLS *TLSConfig
+}
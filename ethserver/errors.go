@@ -0,0 +1,171 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ethserver
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// JSON-RPC error codes, per the Ethereum JSON-RPC conventions.
+const (
+	ErrCodeInvalidParams     = -32602
+	ErrCodeServer            = -32000
+	ErrCodeExecutionReverted = 3
+)
+
+// RPCError is a structured JSON-RPC error. Namespace methods should
+// return one of these (via the constructors below) instead of a bare
+// error, so that callers get a real {code, message, data} envelope
+// instead of an opaque string. It implements Unwrap so errors.Is/As
+// still see through to the underlying SDK error.
+type RPCError struct {
+	Code    int
+	Message string
+	Data    interface{}
+	cause   error
+}
+
+func (e *RPCError) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %s", e.Message, e.cause)
+	}
+	return e.Message
+}
+
+func (e *RPCError) Unwrap() error { return e.cause }
+
+func newRPCError(code int, message string, data interface{}, cause error) *RPCError {
+	return &RPCError{Code: code, Message: message, Data: data, cause: cause}
+}
+
+// ClientError wraps a failure creating a Fabric channel client.
+func ClientError(cause error) *RPCError {
+	return newRPCError(ErrCodeServer, "failed to create channel client", nil, cause)
+}
+
+// EndorsementError wraps a failure returned by the peers that endorsed a
+// proposal, e.g. every peer rejected it or too few endorsed to satisfy
+// the channel's endorsement policy.
+func EndorsementError(cause error) *RPCError {
+	return newRPCError(ErrCodeServer, "endorsement failed", nil, cause)
+}
+
+// MVCCConflictError reports an MVCC_READ_CONFLICT: the transaction's read
+// set was invalidated by another transaction that committed first.
+func MVCCConflictError(cause error) *RPCError {
+	return newRPCError(ErrCodeServer, "transaction invalidated by a concurrent write (MVCC read conflict)", nil, cause)
+}
+
+// ChaincodeNotFoundError reports that the target chaincode is not
+// installed or instantiated on the channel.
+func ChaincodeNotFoundError(chaincodeID string, cause error) *RPCError {
+	return newRPCError(ErrCodeServer, fmt.Sprintf("chaincode not found: %s", chaincodeID), nil, cause)
+}
+
+// InvalidHexError reports a parameter that was expected to be a
+// hex-encoded string but wasn't.
+func InvalidHexError(field string, cause error) *RPCError {
+	return newRPCError(ErrCodeInvalidParams, fmt.Sprintf("invalid hex-encoded %s", field), nil, cause)
+}
+
+// QueryTimeoutError reports a channel query or proposal that didn't
+// complete within the SDK's configured timeout.
+func QueryTimeoutError(cause error) *RPCError {
+	return newRPCError(ErrCodeServer, "query timed out", nil, cause)
+}
+
+// NotSupportedError reports an RPC method that this bridge intentionally
+// does not implement, together with the reason why.
+func NotSupportedError(method, reason string) *RPCError {
+	return newRPCError(ErrCodeServer, fmt.Sprintf("%s is not supported: %s", method, reason), nil, nil)
+}
+
+// revertSelector is the 4-byte selector for Solidity's Error(string),
+// which the EVM writes as the return data of a reverted call.
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+// RevertError decodes the standard Error(string) ABI encoding out of EVM
+// revert data and surfaces the reason in Data.reason, matching how
+// go-ethereum-family clients report execution reverted. If the data
+// isn't in that shape, the reason is simply omitted.
+func RevertError(revertData []byte) *RPCError {
+	rpcErr := &RPCError{Code: ErrCodeExecutionReverted, Message: "execution reverted"}
+
+	if reason, ok := decodeRevertReason(revertData); ok {
+		rpcErr.Message = fmt.Sprintf("execution reverted: %s", reason)
+		rpcErr.Data = map[string]interface{}{"reason": reason}
+	}
+
+	return rpcErr
+}
+
+func decodeRevertReason(data []byte) (string, bool) {
+	const head = 4 + 32 + 32 // selector + offset word + length word
+	if len(data) < head || !bytes.Equal(data[:4], revertSelector) {
+		return "", false
+	}
+
+	length := new(big.Int).SetBytes(data[4+32 : head]).Uint64()
+	if length > uint64(len(data))-uint64(head) {
+		return "", false
+	}
+
+	return string(data[head : uint64(head)+length]), true
+}
+
+// revertDataPattern opportunistically finds hex blobs embedded in an SDK
+// error message. The channel client only gives us the endorsement error
+// as a string, not structured access to the chaincode's return data, so
+// this is how a revert reason gets surfaced at all.
+var revertDataPattern = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+
+func findRevertData(msg string) ([]byte, bool) {
+	for _, match := range revertDataPattern.FindAllString(msg, -1) {
+		data, err := hex.DecodeString(strings.TrimPrefix(match, "0x"))
+		if err != nil {
+			continue
+		}
+		if _, ok := decodeRevertReason(data); ok {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// ClassifyChaincodeError turns an error returned while querying or
+// invoking chaincodeID into the most specific RPCError it can, so
+// callers get an actionable error instead of a raw SDK failure string.
+func ClassifyChaincodeError(chaincodeID string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+
+	if data, ok := findRevertData(msg); ok {
+		return RevertError(data)
+	}
+
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(msg, "MVCC_READ_CONFLICT"):
+		return MVCCConflictError(err)
+	case strings.Contains(msg, "ENDORSEMENT_POLICY_FAILURE") || strings.Contains(lower, "endorsement"):
+		return EndorsementError(err)
+	case strings.Contains(lower, "cannot find chaincode") || strings.Contains(lower, "does not exist") || strings.Contains(lower, "chaincode not found"):
+		return ChaincodeNotFoundError(chaincodeID, err)
+	case strings.Contains(lower, "deadline exceeded") || strings.Contains(lower, "timeout") || strings.Contains(lower, "timed out"):
+		return QueryTimeoutError(err)
+	default:
+		return newRPCError(ErrCodeServer, fmt.Sprintf("failed to query %s", chaincodeID), nil, err)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ethserver
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// buildRevertData constructs the standard Error(string) ABI encoding of a
+// revert reason: selector + offset word + length word + the reason bytes.
+func buildRevertData(reason string) []byte {
+	data := append([]byte{}, revertSelector...)
+
+	offset := make([]byte, 32)
+	offset[31] = 0x20
+	data = append(data, offset...)
+
+	length := make([]byte, 32)
+	lenBytes := big.NewInt(int64(len(reason))).Bytes()
+	copy(length[32-len(lenBytes):], lenBytes)
+	data = append(data, length...)
+
+	return append(data, []byte(reason)...)
+}
+
+func TestDecodeRevertReasonValid(t *testing.T) {
+	data := buildRevertData("insufficient balance")
+
+	reason, ok := decodeRevertReason(data)
+	if !ok {
+		t.Fatal("expected a well-formed revert payload to decode")
+	}
+	if reason != "insufficient balance" {
+		t.Fatalf("reason = %q, want %q", reason, "insufficient balance")
+	}
+}
+
+func TestDecodeRevertReasonTooShort(t *testing.T) {
+	if _, ok := decodeRevertReason(revertSelector); ok {
+		t.Fatal("expected data shorter than the ABI head to be rejected")
+	}
+}
+
+func TestDecodeRevertReasonWrongSelector(t *testing.T) {
+	data := buildRevertData("x")
+	data[0] = 0x00
+
+	if _, ok := decodeRevertReason(data); ok {
+		t.Fatal("expected a mismatched selector to be rejected")
+	}
+}
+
+// TestDecodeRevertReasonOverflowingLength reproduces a crafted revert
+// payload whose length word is close to MaxUint64: adding it to head
+// must not overflow and panic on the slice bounds.
+func TestDecodeRevertReasonOverflowingLength(t *testing.T) {
+	data := buildRevertData("x")
+	for i := 36; i < 68; i++ {
+		data[i] = 0xff
+	}
+
+	if _, ok := decodeRevertReason(data); ok {
+		t.Fatal("expected an absurd length word to be rejected, not to panic")
+	}
+}
+
+func TestClassifyChaincodeErrorNil(t *testing.T) {
+	if err := ClassifyChaincodeError("evmscc", nil); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+func TestClassifyChaincodeErrorRevert(t *testing.T) {
+	data := buildRevertData("insufficient balance")
+	msg := fmt.Sprintf("transaction endorsement failed: 0x%s", hex.EncodeToString(data))
+
+	rpcErr, ok := ClassifyChaincodeError("evmscc", errors.New(msg)).(*RPCError)
+	if !ok {
+		t.Fatalf("expected an *RPCError")
+	}
+	if rpcErr.Code != ErrCodeExecutionReverted {
+		t.Fatalf("code = %d, want %d", rpcErr.Code, ErrCodeExecutionReverted)
+	}
+}
+
+func TestClassifyChaincodeErrorByMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+	}{
+		{"mvcc conflict", "MVCC_READ_CONFLICT"},
+		{"endorsement failure", "ENDORSEMENT_POLICY_FAILURE"},
+		{"chaincode not found", "cannot find chaincode evmscc"},
+		{"timeout", "context deadline exceeded"},
+		{"unrecognized", "some other SDK failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpcErr, ok := ClassifyChaincodeError("evmscc", errors.New(tt.msg)).(*RPCError)
+			if !ok {
+				t.Fatalf("expected an *RPCError")
+			}
+			if rpcErr.Code != ErrCodeServer {
+				t.Fatalf("code = %d, want %d", rpcErr.Code, ErrCodeServer)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package filters implements the eth_newFilter/eth_getFilterChanges family
+// of polling APIs on top of a blockfeed.Feed.
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver/blockfeed"
+)
+
+// DefaultIdleTimeout is how long a filter can go unpolled before the
+// sweeper reclaims it, matching most Ethereum clients' 5 minute default.
+const DefaultIdleTimeout = 5 * time.Minute
+
+type kind int
+
+const (
+	logFilter kind = iota
+	blockFilter
+	pendingTxFilter
+)
+
+// Criteria mirrors the parameters accepted by eth_newFilter. A nil
+// Address/Topics entry matches anything; a topic slot may itself list
+// several values, any of which matches (an "or").
+type Criteria struct {
+	FromBlock string     `json:"fromBlock"`
+	ToBlock   string     `json:"toBlock"`
+	Address   []string   `json:"address"`
+	Topics    [][]string `json:"topics"`
+}
+
+type filter struct {
+	kind     kind
+	criteria Criteria
+	cursor   uint64 // absolute blockfeed sequence number already delivered
+	lastPoll time.Time
+}
+
+// Manager tracks live filters and answers their poll/log requests
+// against a shared blockfeed.Feed.
+type Manager struct {
+	feed  *blockfeed.Feed
+	idle  time.Duration
+	mu    sync.Mutex
+	byID  map[string]*filter
+	next  uint64
+	close chan struct{}
+}
+
+func NewManager(feed *blockfeed.Feed, idleTimeout time.Duration) *Manager {
+	m := &Manager{
+		feed:  feed,
+		idle:  idleTimeout,
+		byID:  map[string]*filter{},
+		close: make(chan struct{}),
+	}
+	go m.sweep()
+	return m
+}
+
+func (m *Manager) sweep() {
+	ticker := time.NewTicker(m.idle)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.mu.Lock()
+			for id, f := range m.byID {
+				if time.Since(f.lastPoll) > m.idle {
+					delete(m.byID, id)
+				}
+			}
+			m.mu.Unlock()
+		case <-m.close:
+			return
+		}
+	}
+}
+
+// Stop halts the idle-filter sweeper.
+func (m *Manager) Stop() {
+	close(m.close)
+}
+
+func (m *Manager) register(k kind, c Criteria) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := "0x" + strconv.FormatUint(m.next, 16)
+	m.next++
+
+	cursor := m.feed.CurrentSeq()
+	m.byID[id] = &filter{kind: k, criteria: c, cursor: cursor, lastPoll: time.Now()}
+
+	return id
+}
+
+func (m *Manager) NewFilter(c Criteria) string {
+	return m.register(logFilter, c)
+}
+
+func (m *Manager) NewBlockFilter() string {
+	return m.register(blockFilter, Criteria{})
+}
+
+func (m *Manager) NewPendingTransactionFilter() string {
+	return m.register(pendingTxFilter, Criteria{})
+}
+
+// Uninstall removes a filter, returning false if it did not exist.
+func (m *Manager) Uninstall(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.byID[id]; !ok {
+		return false
+	}
+	delete(m.byID, id)
+	return true
+}
+
+// GetFilterChanges returns whatever is new since the filter was last
+// polled: block hashes for a block filter, transaction hashes for a
+// pending-transaction filter, or matching logs for a log filter.
+func (m *Manager) GetFilterChanges(id string) (interface{}, error) {
+	m.mu.Lock()
+	f, ok := m.byID[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("filter not found: %s", id)
+	}
+	f.lastPoll = time.Now()
+	cursor := f.cursor
+	m.mu.Unlock()
+
+	fresh, nextCursor := m.feed.Since(cursor)
+
+	m.mu.Lock()
+	f.cursor = nextCursor
+	m.mu.Unlock()
+
+	switch f.kind {
+	case blockFilter:
+		hashes := make([]string, 0, len(fresh))
+		for _, rec := range fresh {
+			hashes = append(hashes, rec.Header.Hash)
+		}
+		return hashes, nil
+	case pendingTxFilter:
+		var txs []string
+		for _, rec := range fresh {
+			for _, tx := range rec.TxIDs {
+				txs = append(txs, "0x"+tx)
+			}
+		}
+		return txs, nil
+	default:
+		return matchingLogs(fresh, f.criteria), nil
+	}
+}
+
+// GetFilterLogs returns every matching log in the feed's retained history
+// (the most recent blocks only), ignoring the filter's poll cursor.
+func (m *Manager) GetFilterLogs(id string) ([]ethserver.Log, error) {
+	m.mu.Lock()
+	f, ok := m.byID[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("filter not found: %s", id)
+	}
+	f.lastPoll = time.Now()
+	criteria := f.criteria
+	m.mu.Unlock()
+
+	return matchingLogs(m.feed.History(), criteria), nil
+}
+
+func matchingLogs(records []blockfeed.Record, c Criteria) []ethserver.Log {
+	var logs []ethserver.Log
+	for _, rec := range records {
+		for _, log := range rec.Logs {
+			if matches(c, log) {
+				logs = append(logs, log)
+			}
+		}
+	}
+	return logs
+}
+
+func matches(c Criteria, log ethserver.Log) bool {
+	if !inBlockRange(c, log.BlockNumber) {
+		return false
+	}
+
+	if len(c.Address) > 0 && !contains(c.Address, log.Address) {
+		return false
+	}
+
+	if len(c.Topics) > len(log.Topics) {
+		return false
+	}
+
+	for i, wanted := range c.Topics {
+		if len(wanted) == 0 {
+			continue // wildcard slot
+		}
+		if !contains(wanted, log.Topics[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// inBlockRange reports whether blockNumberHex falls within [FromBlock,
+// ToBlock]. An unparseable block number is let through rather than
+// silently dropped.
+func inBlockRange(c Criteria, blockNumberHex string) bool {
+	num, err := strconv.ParseUint(ethserver.Strip0xFromHex(blockNumberHex), 16, 64)
+	if err != nil {
+		return true
+	}
+
+	if from, ok := parseBlockBound(c.FromBlock); ok && num < from {
+		return false
+	}
+	if to, ok := parseBlockBound(c.ToBlock); ok && num > to {
+		return false
+	}
+
+	return true
+}
+
+// parseBlockBound turns an eth_newFilter block tag into a concrete bound.
+// "latest"/"pending"/"" impose no bound since this bridge has no notion
+// of a pending block distinct from the latest one; "earliest" is block 0.
+func parseBlockBound(tag string) (uint64, bool) {
+	switch tag {
+	case "", "latest", "pending":
+		return 0, false
+	case "earliest":
+		return 0, true
+	default:
+		n, err := strconv.ParseUint(ethserver.Strip0xFromHex(tag), 16, 64)
+		if err != nil {
+			return 0, false
+		}
+		return n, true
+	}
+}
+
+func contains(haystack []string, needle string) bool {
+	for _, v := range haystack {
+		if strings.EqualFold(v, needle) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filters
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver/blockfeed"
+)
+
+func TestMatchesFiltersByBlockRange(t *testing.T) {
+	log := ethserver.Log{BlockNumber: "0xa"} // block 10
+
+	tests := []struct {
+		name      string
+		criteria  Criteria
+		wantMatch bool
+	}{
+		{"no range", Criteria{}, true},
+		{"within range", Criteria{FromBlock: "0x5", ToBlock: "0xf"}, true},
+		{"below from", Criteria{FromBlock: "0xb"}, false},
+		{"above to", Criteria{ToBlock: "0x9"}, false},
+		{"earliest to latest", Criteria{FromBlock: "earliest", ToBlock: "latest"}, true},
+		{"latest from has no lower bound", Criteria{FromBlock: "latest"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matches(tt.criteria, log); got != tt.wantMatch {
+				t.Fatalf("matches(%+v, block 0xa) = %v, want %v", tt.criteria, got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestMatchesFiltersByAddressAndTopics(t *testing.T) {
+	log := ethserver.Log{
+		Address:     "0xabc",
+		Topics:      []string{"0x1", "0x2"},
+		BlockNumber: "0x1",
+	}
+
+	if !matches(Criteria{Address: []string{"0xABC"}}, log) {
+		t.Fatal("expected case-insensitive address match")
+	}
+	if matches(Criteria{Address: []string{"0xdef"}}, log) {
+		t.Fatal("expected address mismatch to filter the log out")
+	}
+	if !matches(Criteria{Topics: [][]string{{"0x1"}, nil}}, log) {
+		t.Fatal("expected a wildcard topic slot to match anything")
+	}
+	if matches(Criteria{Topics: [][]string{{"0x1"}, {"0x3"}}}, log) {
+		t.Fatal("expected a non-matching topic slot to filter the log out")
+	}
+	if matches(Criteria{Topics: [][]string{{"0x1"}, {"0x2"}, {"0x3"}}}, log) {
+		t.Fatal("expected more topic slots than the log has to filter it out")
+	}
+}
+
+func TestMatchingLogsAppliesCriteriaAcrossRecords(t *testing.T) {
+	records := []blockfeed.Record{
+		{Logs: []ethserver.Log{{Address: "0xa", BlockNumber: "0x1"}}},
+		{Logs: []ethserver.Log{{Address: "0xb", BlockNumber: "0x2"}}},
+	}
+
+	logs := matchingLogs(records, Criteria{Address: []string{"0xb"}})
+	if len(logs) != 1 || logs[0].Address != "0xb" {
+		t.Fatalf("expected exactly the log matching the address filter, got %+v", logs)
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package gas provides a configurable per-operation cost model.
+// fabric-chaincode-evm does not meter gas while a transaction executes -
+// there is no notion of running out of gas on a Fabric peer - so
+// GasUsed/CumulativeGasUsed and eth_estimateGas all fall back to this
+// approximation of go-ethereum's intrinsic gas calculation.
+package gas
+
+// Model holds the per-operation costs used to estimate gas usage.
+type Model struct {
+	TxGas            uint64
+	TxDataZeroGas    uint64
+	TxDataNonZeroGas uint64
+}
+
+// Default mirrors go-ethereum's intrinsic gas constants.
+var Default = Model{
+	TxGas:            21000,
+	TxDataZeroGas:    4,
+	TxDataNonZeroGas: 68,
+}
+
+// Estimate returns the estimated gas cost of a transaction carrying data.
+func (m Model) Estimate(data []byte) uint64 {
+	total := m.TxGas
+	for _, b := range data {
+		if b == 0 {
+			total += m.TxDataZeroGas
+		} else {
+			total += m.TxDataNonZeroGas
+		}
+	}
+	return total
+}
@@ -0,0 +1,32 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gas
+
+import "testing"
+
+func TestEstimate(t *testing.T) {
+	m := Model{TxGas: 21000, TxDataZeroGas: 4, TxDataNonZeroGas: 68}
+
+	tests := []struct {
+		name string
+		data []byte
+		want uint64
+	}{
+		{"no data", nil, 21000},
+		{"all zero bytes", []byte{0, 0, 0}, 21000 + 3*4},
+		{"all non-zero bytes", []byte{1, 2, 3}, 21000 + 3*68},
+		{"mixed bytes", []byte{0, 1, 0, 2}, 21000 + 2*4 + 2*68},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Estimate(tt.data); got != tt.want {
+				t.Fatalf("Estimate(%v) = %d, want %d", tt.data, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package identity resolves a Fabric MSP identity into the EVM address
+// evmscc assigns it, so that RPC responses can report a `from` field.
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/msp"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+)
+
+// AddressFromCreator derives the 20-byte EVM address evmscc assigns to
+// the identity that created or endorsed a transaction, from the raw
+// SignatureHeader.Creator bytes (a marshaled msp.SerializedIdentity).
+// This mirrors evmscc's own account derivation: keccak256 of the
+// uncompressed EC public key, keeping the low 20 bytes.
+func AddressFromCreator(creator []byte) (string, error) {
+	sID := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(creator, sID); err != nil {
+		return "", fmt.Errorf("failed to unmarshal creator: %s", err)
+	}
+
+	block, _ := pem.Decode(sID.GetIdBytes())
+	if block == nil {
+		return "", fmt.Errorf("failed to decode PEM block from creator certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse creator certificate: %s", err)
+	}
+
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("creator certificate does not use an ECDSA public key")
+	}
+
+	pubBytes := elliptic.Marshal(pub.Curve, pub.X, pub.Y)[1:] // drop the uncompressed-point prefix byte
+	hash := ethserver.Keccak256(pubBytes)
+
+	return "0x" + hex.EncodeToString(hash[len(hash)-20:]), nil
+}
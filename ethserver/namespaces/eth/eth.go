@@ -0,0 +1,697 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package eth implements the `eth_*` JSON-RPC namespace on top of the
+// evmscc/qscc system chaincodes.
+package eth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/api/apitxn"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver/filters"
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver/gas"
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver/identity"
+)
+
+// transactionsFilterIndex is common.BlockMetadataIndex_TRANSACTIONS_FILTER.
+const transactionsFilterIndex = 2
+
+// Service implements the eth namespace by querying evmscc/qscc over a
+// Fabric channel client.
+type Service struct {
+	sdk       *fabsdk.FabricSDK
+	user      string
+	channelID string
+	evmscc    string
+	qscc      string
+	filters   *filters.Manager
+	gasModel  gas.Model
+}
+
+// New constructs the eth namespace service. filterMgr may be nil, in
+// which case eth_newFilter and friends report an error instead of
+// panicking - a server can run without the block-event feed they depend
+// on.
+func New(sdk *fabsdk.FabricSDK, user string, cfg ethserver.Config, filterMgr *filters.Manager) *Service {
+	return &Service{
+		sdk:       sdk,
+		user:      user,
+		channelID: cfg.ChannelID,
+		evmscc:    cfg.EVMSCC,
+		qscc:      cfg.QSCC,
+		filters:   filterMgr,
+		gasModel:  gas.Default,
+	}
+}
+
+func (s *Service) Namespace() string { return "eth" }
+
+func (s *Service) channelClient() (apitxn.ChannelClient, error) {
+	chClient, err := s.sdk.NewChannelClient(s.channelID, s.user)
+	if err != nil {
+		return nil, ethserver.ClientError(err)
+	}
+	return chClient, nil
+}
+
+func (s *Service) GetCode(r *http.Request, args *ethserver.DataParam, reply *string) error {
+	fmt.Println("Recieved a request for eth_getCode")
+
+	chClient, err := s.channelClient()
+	if err != nil {
+		return err
+	}
+	defer chClient.Close()
+
+	queryArgs := [][]byte{[]byte(ethserver.Strip0xFromHex(string(*args)))}
+
+	value, err := ethserver.Query(chClient, s.evmscc, "getCode", queryArgs)
+	if err != nil {
+		return err
+	}
+	*reply = string(value)
+
+	return nil
+}
+
+func (s *Service) Call(r *http.Request, params *ethserver.Params, reply *string) error {
+	fmt.Println("Received a request for eth_call")
+	fmt.Printf("Data that is being sent:%s \n\n", params.Data)
+
+	chClient, err := s.channelClient()
+	if err != nil {
+		return err
+	}
+	defer chClient.Close()
+
+	args := [][]byte{[]byte(ethserver.Strip0xFromHex(params.Data))}
+
+	value, err := ethserver.Query(chClient, s.evmscc, ethserver.Strip0xFromHex(params.To), args)
+	if err != nil {
+		return err
+	}
+
+	*reply = "0x" + hex.EncodeToString(value)
+
+	return nil
+}
+
+func (s *Service) SendTransaction(r *http.Request, params *ethserver.Params, reply *string) error {
+	fmt.Println("Recieved a request for eth_sendTransaction")
+	fmt.Printf("Data that is being sent:%s \n\n", params.Data)
+
+	chClient, err := s.channelClient()
+	if err != nil {
+		return err
+	}
+	defer chClient.Close()
+
+	if params.To == "" {
+		params.To = hex.EncodeToString(ethserver.ZeroAddress)
+	}
+
+	txReq := apitxn.ExecuteTxRequest{
+		ChaincodeID: s.evmscc,
+		Fcn:         ethserver.Strip0xFromHex(params.To),
+		Args:        [][]byte{[]byte(ethserver.Strip0xFromHex(params.Data))},
+	}
+
+	// Return only the transaction ID.
+	// Maybe change to an async transaction.
+	_, txID, err := chClient.ExecuteTx(txReq)
+	if err != nil {
+		return ethserver.ClassifyChaincodeError(s.evmscc, err)
+	}
+
+	*reply = txID.ID
+
+	return nil
+}
+
+func (s *Service) GetTransactionReceipt(r *http.Request, param *ethserver.DataParam, reply *ethserver.TxReceipt) error {
+	fmt.Println("Recieved a request for eth_getTransactionReceipt")
+
+	chClient, err := s.channelClient()
+	if err != nil {
+		return err
+	}
+	defer chClient.Close()
+
+	rtx, err := s.resolveTx(chClient, string(*param))
+	if err != nil {
+		return err
+	}
+
+	blkHeader := rtx.block.GetHeader()
+	blockHash := hex.EncodeToString(blkHeader.Hash())
+	blockNumber := "0x" + strconv.FormatUint(blkHeader.GetNumber(), 16)
+
+	txArgs := rtx.invokeSpec.GetChaincodeSpec().GetInput().Args
+	// First arg is the callee address. If it is the zero address, the tx was a contract creation.
+	callee, err := hex.DecodeString(string(txArgs[0]))
+	if err != nil {
+		return ethserver.InvalidHexError("callee address", err)
+	}
+
+	var data []byte
+	if len(txArgs) > 1 {
+		data = txArgs[1]
+	}
+
+	cumulativeGas, err := s.cumulativeGas(rtx.block, rtx.txIndex)
+	if err != nil {
+		return err
+	}
+
+	logs, err := ethserver.DecodeEVMLogs(rtx.respPayload.GetEvents())
+	if err != nil {
+		return err
+	}
+	for i := range logs {
+		logs[i].BlockHash = "0x" + blockHash
+		logs[i].BlockNumber = "0x" + strconv.FormatUint(blkHeader.GetNumber(), 16)
+		logs[i].TransactionHash = string(*param)
+		logs[i].TransactionIndex = "0x" + strconv.Itoa(rtx.txIndex)
+		logs[i].LogIndex = "0x" + strconv.Itoa(i)
+	}
+
+	receipt := ethserver.TxReceipt{
+		TransactionHash:   string(*param),
+		BlockHash:         "0x" + blockHash,
+		BlockNumber:       blockNumber,
+		TransactionIndex:  "0x" + strconv.Itoa(rtx.txIndex),
+		From:              rtx.from,
+		Status:            rtx.status,
+		Logs:              logs,
+		LogsBloom:         "0x" + hex.EncodeToString(logsBloom(logs)),
+		GasUsed:           int(s.gasModel.Estimate(data)),
+		CumulativeGasUsed: cumulativeGas,
+	}
+
+	if bytes.Equal(callee, ethserver.ZeroAddress) {
+		receipt.ContractAddress = string(rtx.respPayload.GetResponse().GetPayload())
+	} else {
+		receipt.To = "0x" + hex.EncodeToString(callee)
+	}
+	*reply = receipt
+
+	return nil
+}
+
+// GetTransactionByHash returns the standard Ethereum transaction shape
+// for a transaction ID, reconstructed from the ChaincodeInvocationSpec
+// evmscc was invoked with.
+func (s *Service) GetTransactionByHash(r *http.Request, param *ethserver.DataParam, reply *ethserver.Transaction) error {
+	fmt.Println("Recieved a request for eth_getTransactionByHash")
+
+	chClient, err := s.channelClient()
+	if err != nil {
+		return err
+	}
+	defer chClient.Close()
+
+	rtx, err := s.resolveTx(chClient, string(*param))
+	if err != nil {
+		return err
+	}
+
+	txArgs := rtx.invokeSpec.GetChaincodeSpec().GetInput().Args
+	callee, err := hex.DecodeString(string(txArgs[0]))
+	if err != nil {
+		return ethserver.InvalidHexError("callee address", err)
+	}
+
+	to := ""
+	if !bytes.Equal(callee, ethserver.ZeroAddress) {
+		to = "0x" + hex.EncodeToString(callee)
+	}
+
+	input := ""
+	if len(txArgs) > 1 {
+		input = "0x" + hex.EncodeToString(txArgs[1])
+	}
+
+	*reply = ethserver.Transaction{
+		Hash: string(*param),
+		// Fabric does not use account nonces to order transactions.
+		Nonce:            "0x0",
+		BlockHash:        "0x" + hex.EncodeToString(rtx.block.GetHeader().Hash()),
+		BlockNumber:      "0x" + strconv.FormatUint(rtx.block.GetHeader().GetNumber(), 16),
+		TransactionIndex: "0x" + strconv.Itoa(rtx.txIndex),
+		From:             rtx.from,
+		To:               to,
+		// No ether changes hands on a Fabric channel.
+		Value: "0x0",
+		Input: input,
+	}
+
+	return nil
+}
+
+// GetBlockByNumber returns the standard Ethereum block shape for a block
+// height, which may be given as a hex-encoded quantity or one of the
+// "latest"/"earliest"/"pending" tags. Fabric endorses and commits in one
+// flow, so "pending" is treated the same as "latest".
+func (s *Service) GetBlockByNumber(r *http.Request, param *ethserver.DataParam, reply *ethserver.Block) error {
+	fmt.Println("Recieved a request for eth_getBlockByNumber")
+
+	chClient, err := s.channelClient()
+	if err != nil {
+		return err
+	}
+	defer chClient.Close()
+
+	number, err := s.resolveBlockNumber(chClient, string(*param))
+	if err != nil {
+		return err
+	}
+
+	b, err := ethserver.Query(chClient, s.qscc, "GetBlockByNumber", [][]byte{[]byte(s.channelID), []byte(strconv.FormatUint(number, 10))})
+	if err != nil {
+		return err
+	}
+
+	block := &common.Block{}
+	if err := proto.Unmarshal(b, block); err != nil {
+		return err
+	}
+
+	*reply = blockFromCommon(block)
+	return nil
+}
+
+// GetBlockByHash returns the standard Ethereum block shape for a block
+// hash.
+func (s *Service) GetBlockByHash(r *http.Request, param *ethserver.DataParam, reply *ethserver.Block) error {
+	fmt.Println("Recieved a request for eth_getBlockByHash")
+
+	chClient, err := s.channelClient()
+	if err != nil {
+		return err
+	}
+	defer chClient.Close()
+
+	hash, err := hex.DecodeString(ethserver.Strip0xFromHex(string(*param)))
+	if err != nil {
+		return ethserver.InvalidHexError("block hash", err)
+	}
+
+	b, err := ethserver.Query(chClient, s.qscc, "GetBlockByHash", [][]byte{[]byte(s.channelID), hash})
+	if err != nil {
+		return err
+	}
+
+	block := &common.Block{}
+	if err := proto.Unmarshal(b, block); err != nil {
+		return err
+	}
+
+	*reply = blockFromCommon(block)
+	return nil
+}
+
+// resolveBlockNumber turns a hex-encoded quantity or "latest"/"earliest"/
+// "pending" tag into an absolute block height.
+func (s *Service) resolveBlockNumber(chClient apitxn.ChannelClient, tag string) (uint64, error) {
+	switch tag {
+	case "", "latest", "pending":
+		info, err := s.chainInfo(chClient)
+		if err != nil {
+			return 0, err
+		}
+		return info.GetHeight() - 1, nil
+	case "earliest":
+		return 0, nil
+	default:
+		number, err := strconv.ParseUint(ethserver.Strip0xFromHex(tag), 16, 64)
+		if err != nil {
+			return 0, ethserver.InvalidHexError("block number", err)
+		}
+		return number, nil
+	}
+}
+
+// blockFromCommon converts a Fabric block into the standard Ethereum
+// block shape, listing the hashes of the transactions it contains.
+func blockFromCommon(block *common.Block) ethserver.Block {
+	header := block.GetHeader()
+
+	var txs []string
+	for _, envBytes := range block.GetData().GetData() {
+		envelope := &common.Envelope{}
+		if err := proto.Unmarshal(envBytes, envelope); err != nil {
+			continue
+		}
+
+		payload := &common.Payload{}
+		if err := proto.Unmarshal(envelope.GetPayload(), payload); err != nil {
+			continue
+		}
+
+		chdr := &common.ChannelHeader{}
+		if err := proto.Unmarshal(payload.GetHeader().GetChannelHeader(), chdr); err != nil {
+			continue
+		}
+
+		txs = append(txs, "0x"+chdr.GetTxId())
+	}
+
+	return ethserver.Block{
+		Number:       "0x" + strconv.FormatUint(header.GetNumber(), 16),
+		Hash:         "0x" + hex.EncodeToString(header.Hash()),
+		ParentHash:   "0x" + hex.EncodeToString(header.GetPreviousHash()),
+		Transactions: txs,
+	}
+}
+
+// resolvedTx bundles everything derived from a qscc lookup of a single
+// transaction, shared by GetTransactionReceipt and GetTransactionByHash.
+type resolvedTx struct {
+	block       *common.Block
+	txIndex     int
+	status      string
+	respPayload *peer.ChaincodeAction
+	invokeSpec  *peer.ChaincodeInvocationSpec
+	from        string
+}
+
+func (s *Service) resolveTx(chClient apitxn.ChannelClient, txID string) (*resolvedTx, error) {
+	args := [][]byte{[]byte(s.channelID), []byte(txID)}
+
+	t, err := ethserver.Query(chClient, s.qscc, "GetTransactionByID", args)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &peer.ProcessedTransaction{}
+	if err := proto.Unmarshal(t, tx); err != nil {
+		return nil, err
+	}
+
+	b, err := ethserver.Query(chClient, s.qscc, "GetBlockByTxID", args)
+	if err != nil {
+		return nil, err
+	}
+
+	block := &common.Block{}
+	if err := proto.Unmarshal(b, block); err != nil {
+		return nil, err
+	}
+
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(tx.GetTransactionEnvelope().GetPayload(), payload); err != nil {
+		return nil, err
+	}
+
+	txActions := &peer.Transaction{}
+	if err := proto.Unmarshal(payload.GetData(), txActions); err != nil {
+		return nil, err
+	}
+
+	actions := txActions.GetActions()
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("transaction %s has no actions", txID)
+	}
+
+	ccPropPayload, respPayload, err := ethserver.GetPayloads(actions[0])
+	if err != nil {
+		return nil, err
+	}
+
+	invokeSpec := &peer.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(ccPropPayload.Input, invokeSpec); err != nil {
+		return nil, err
+	}
+
+	sigHeader := &common.SignatureHeader{}
+	if err := proto.Unmarshal(payload.GetHeader().GetSignatureHeader(), sigHeader); err != nil {
+		return nil, err
+	}
+
+	from, err := identity.AddressFromCreator(sigHeader.GetCreator())
+	if err != nil {
+		return nil, err
+	}
+
+	txIndex, status, err := txIndexAndStatus(block, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resolvedTx{
+		block:       block,
+		txIndex:     txIndex,
+		status:      status,
+		respPayload: respPayload,
+		invokeSpec:  invokeSpec,
+		from:        from,
+	}, nil
+}
+
+// txIndexAndStatus locates txID within block and reports its validation
+// status from the block's transaction-filter metadata: "0x1" for a valid,
+// committed transaction and "0x0" for anything else.
+func txIndexAndStatus(block *common.Block, txID string) (int, string, error) {
+	for i, envBytes := range block.GetData().GetData() {
+		envelope := &common.Envelope{}
+		if err := proto.Unmarshal(envBytes, envelope); err != nil {
+			return 0, "", err
+		}
+
+		payload := &common.Payload{}
+		if err := proto.Unmarshal(envelope.GetPayload(), payload); err != nil {
+			return 0, "", err
+		}
+
+		chdr := &common.ChannelHeader{}
+		if err := proto.Unmarshal(payload.GetHeader().GetChannelHeader(), chdr); err != nil {
+			return 0, "", err
+		}
+
+		if chdr.GetTxId() != txID {
+			continue
+		}
+
+		status := "0x0"
+		if filters := block.GetMetadata().GetMetadata(); len(filters) > transactionsFilterIndex && i < len(filters[transactionsFilterIndex]) {
+			if peer.TxValidationCode(filters[transactionsFilterIndex][i]) == peer.TxValidationCode_VALID {
+				status = "0x1"
+			}
+		}
+
+		return i, status, nil
+	}
+
+	return 0, "", fmt.Errorf("transaction %s not found in its own block", txID)
+}
+
+// cumulativeGas sums the estimated gas cost of every transaction in block
+// up to and including uptoIndex.
+func (s *Service) cumulativeGas(block *common.Block, uptoIndex int) (int, error) {
+	var total uint64
+
+	for i := 0; i <= uptoIndex && i < len(block.GetData().GetData()); i++ {
+		envelope := &common.Envelope{}
+		if err := proto.Unmarshal(block.GetData().GetData()[i], envelope); err != nil {
+			return 0, err
+		}
+
+		payload := &common.Payload{}
+		if err := proto.Unmarshal(envelope.GetPayload(), payload); err != nil {
+			return 0, err
+		}
+
+		txActions := &peer.Transaction{}
+		if err := proto.Unmarshal(payload.GetData(), txActions); err != nil {
+			return 0, err
+		}
+
+		for _, action := range txActions.GetActions() {
+			ccPropPayload, _, err := ethserver.GetPayloads(action)
+			if err != nil {
+				continue
+			}
+
+			invokeSpec := &peer.ChaincodeInvocationSpec{}
+			if err := proto.Unmarshal(ccPropPayload.Input, invokeSpec); err != nil {
+				continue
+			}
+
+			inArgs := invokeSpec.GetChaincodeSpec().GetInput().Args
+			var data []byte
+			if len(inArgs) > 1 {
+				data = inArgs[1]
+			}
+			total += s.gasModel.Estimate(data)
+		}
+	}
+
+	return int(total), nil
+}
+
+// logsBloom computes the standard 2048-bit Ethereum log bloom filter for
+// a set of logs, using the same 3-hash bloom9 scheme as go-ethereum.
+func logsBloom(logs []ethserver.Log) []byte {
+	bloom := make([]byte, 256)
+
+	add := func(data []byte) {
+		hash := ethserver.Keccak256(data)
+		for i := 0; i < 6; i += 2 {
+			bit := (uint(hash[i+1]) + (uint(hash[i]) << 8)) & 2047
+			bloom[256-1-bit/8] |= 1 << (bit % 8)
+		}
+	}
+
+	for _, log := range logs {
+		if addr, err := hex.DecodeString(ethserver.Strip0xFromHex(log.Address)); err == nil {
+			add(addr)
+		}
+		for _, topic := range log.Topics {
+			if t, err := hex.DecodeString(ethserver.Strip0xFromHex(topic)); err == nil {
+				add(t)
+			}
+		}
+	}
+
+	return bloom
+}
+
+// BlockNumber returns the number of the channel's latest block (the
+// ledger height minus one) as a hex-encoded quantity.
+func (s *Service) BlockNumber(r *http.Request, _ *ethserver.Empty, reply *string) error {
+	chClient, err := s.channelClient()
+	if err != nil {
+		return err
+	}
+	defer chClient.Close()
+
+	info, err := s.chainInfo(chClient)
+	if err != nil {
+		return err
+	}
+
+	*reply = "0x" + strconv.FormatUint(info.GetHeight()-1, 16)
+	return nil
+}
+
+// GetBalance always reports a zero balance: fabric-chaincode-evm does not
+// meter or hold value, so there is no notion of an account balance to
+// query for.
+func (s *Service) GetBalance(r *http.Request, args *ethserver.DataParam, reply *string) error {
+	*reply = "0x0"
+	return nil
+}
+
+// GasPrice is always zero: transactions are ordered and executed by the
+// Fabric endorsers/orderers, not paid for in gas.
+func (s *Service) GasPrice(r *http.Request, _ *ethserver.Empty, reply *string) error {
+	*reply = "0x0"
+	return nil
+}
+
+// EstimateGas approximates go-ethereum's intrinsic gas calculation from
+// the size of the call data; fabric-chaincode-evm does not meter gas
+// during execution, so this is only useful as a rough client-side hint.
+func (s *Service) EstimateGas(r *http.Request, params *ethserver.Params, reply *string) error {
+	data, err := hex.DecodeString(ethserver.Strip0xFromHex(params.Data))
+	if err != nil {
+		return ethserver.InvalidHexError("data", err)
+	}
+
+	*reply = "0x" + strconv.FormatUint(s.gasModel.Estimate(data), 16)
+	return nil
+}
+
+// ChainId always reports 0: fabric-chaincode-evm has no numeric chain
+// identifier of its own, and none is derived from the channel.
+func (s *Service) ChainId(r *http.Request, _ *ethserver.Empty, reply *string) error {
+	*reply = "0x0"
+	return nil
+}
+
+// NewFilter creates a log filter and returns its ID.
+func (s *Service) NewFilter(r *http.Request, criteria *filters.Criteria, reply *string) error {
+	if s.filters == nil {
+		return ethserver.NotSupportedError("eth_newFilter", "no block-event feed configured")
+	}
+	*reply = s.filters.NewFilter(*criteria)
+	return nil
+}
+
+// NewBlockFilter creates a filter that reports new block hashes.
+func (s *Service) NewBlockFilter(r *http.Request, _ *ethserver.Empty, reply *string) error {
+	if s.filters == nil {
+		return ethserver.NotSupportedError("eth_newBlockFilter", "no block-event feed configured")
+	}
+	*reply = s.filters.NewBlockFilter()
+	return nil
+}
+
+// NewPendingTransactionFilter creates a filter that reports newly
+// committed transaction hashes.
+func (s *Service) NewPendingTransactionFilter(r *http.Request, _ *ethserver.Empty, reply *string) error {
+	if s.filters == nil {
+		return ethserver.NotSupportedError("eth_newPendingTransactionFilter", "no block-event feed configured")
+	}
+	*reply = s.filters.NewPendingTransactionFilter()
+	return nil
+}
+
+func (s *Service) GetFilterChanges(r *http.Request, id *ethserver.DataParam, reply *interface{}) error {
+	if s.filters == nil {
+		return ethserver.NotSupportedError("eth_getFilterChanges", "no block-event feed configured")
+	}
+	changes, err := s.filters.GetFilterChanges(string(*id))
+	if err != nil {
+		return err
+	}
+	*reply = changes
+	return nil
+}
+
+func (s *Service) GetFilterLogs(r *http.Request, id *ethserver.DataParam, reply *[]ethserver.Log) error {
+	if s.filters == nil {
+		return ethserver.NotSupportedError("eth_getFilterLogs", "no block-event feed configured")
+	}
+	logs, err := s.filters.GetFilterLogs(string(*id))
+	if err != nil {
+		return err
+	}
+	*reply = logs
+	return nil
+}
+
+func (s *Service) UninstallFilter(r *http.Request, id *ethserver.DataParam, reply *bool) error {
+	if s.filters == nil {
+		return ethserver.NotSupportedError("eth_uninstallFilter", "no block-event feed configured")
+	}
+	*reply = s.filters.Uninstall(string(*id))
+	return nil
+}
+
+func (s *Service) chainInfo(chClient apitxn.ChannelClient) (*common.BlockchainInfo, error) {
+	value, err := ethserver.Query(chClient, s.qscc, "GetChainInfo", [][]byte{[]byte(s.channelID)})
+	if err != nil {
+		return nil, err
+	}
+
+	info := &common.BlockchainInfo{}
+	if err := proto.Unmarshal(value, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/protos/common"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+)
+
+func TestLogsBloomIsEmptyForNoLogs(t *testing.T) {
+	bloom := logsBloom(nil)
+
+	if len(bloom) != 256 {
+		t.Fatalf("expected a 256-byte bloom filter, got %d bytes", len(bloom))
+	}
+	for _, b := range bloom {
+		if b != 0 {
+			t.Fatal("expected an all-zero bloom filter for no logs")
+		}
+	}
+}
+
+func TestLogsBloomSetsBitsForAddressAndTopics(t *testing.T) {
+	empty := logsBloom(nil)
+	withLog := logsBloom([]ethserver.Log{
+		{Address: "0xdeadbeef00000000000000000000000000000000", Topics: []string{"0x01"}},
+	})
+
+	if len(withLog) != 256 {
+		t.Fatalf("expected a 256-byte bloom filter, got %d bytes", len(withLog))
+	}
+
+	same := true
+	for i := range withLog {
+		if withLog[i] != empty[i] {
+			same = false
+			break
+		}
+	}
+	if same {
+		t.Fatal("expected the bloom filter to change once a log's address/topics are added")
+	}
+}
+
+func TestResolveBlockNumberEarliestAndHex(t *testing.T) {
+	s := &Service{}
+
+	if n, err := s.resolveBlockNumber(nil, "earliest"); err != nil || n != 0 {
+		t.Fatalf("resolveBlockNumber(earliest) = %d, %v, want 0, nil", n, err)
+	}
+	if n, err := s.resolveBlockNumber(nil, "0x2a"); err != nil || n != 42 {
+		t.Fatalf("resolveBlockNumber(0x2a) = %d, %v, want 42, nil", n, err)
+	}
+	if _, err := s.resolveBlockNumber(nil, "not-hex"); err == nil {
+		t.Fatal("expected an error for a non-hex block number")
+	}
+}
+
+func TestBlockFromCommon(t *testing.T) {
+	block := &common.Block{
+		Header: &common.BlockHeader{
+			Number:       42,
+			PreviousHash: []byte{0xaa, 0xbb},
+		},
+		Data: &common.BlockData{},
+	}
+
+	got := blockFromCommon(block)
+
+	if got.Number != "0x2a" {
+		t.Fatalf("Number = %q, want %q", got.Number, "0x2a")
+	}
+	if got.ParentHash != "0xaabb" {
+		t.Fatalf("ParentHash = %q, want %q", got.ParentHash, "0xaabb")
+	}
+	if got.Hash == "" {
+		t.Fatal("expected a non-empty block hash")
+	}
+	if len(got.Transactions) != 0 {
+		t.Fatalf("expected no transactions for an empty block, got %v", got.Transactions)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package net implements the `net_*` JSON-RPC namespace.
+package net
+
+import (
+	"net/http"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+)
+
+// Service implements the net namespace. The Fabric channel a client is
+// pointed at stands in for an Ethereum network id.
+type Service struct {
+	channelID string
+}
+
+func New(cfg ethserver.Config) *Service {
+	return &Service{channelID: cfg.ChannelID}
+}
+
+func (s *Service) Namespace() string { return "net" }
+
+func (s *Service) Version(r *http.Request, _ *ethserver.Empty, reply *string) error {
+	*reply = s.channelID
+	return nil
+}
+
+// Listening always reports true: a running server is, by definition,
+// listening for the peer connections that back it.
+func (s *Service) Listening(r *http.Request, _ *ethserver.Empty, reply *bool) error {
+	*reply = true
+	return nil
+}
+
+// PeerCount is always zero: fabric-chaincode-evm has no notion of
+// devp2p-style peers, only the Fabric peers configured in the SDK.
+func (s *Service) PeerCount(r *http.Request, _ *ethserver.Empty, reply *string) error {
+	*reply = "0x0"
+	return nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package net
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+)
+
+func TestVersionReportsChannelID(t *testing.T) {
+	svc := New(ethserver.Config{ChannelID: "mychannel"})
+
+	var reply string
+	if err := svc.Version(nil, &ethserver.Empty{}, &reply); err != nil {
+		t.Fatalf("Version returned an error: %s", err)
+	}
+	if reply != "mychannel" {
+		t.Fatalf("expected version %q, got %q", "mychannel", reply)
+	}
+}
+
+func TestListeningIsAlwaysTrue(t *testing.T) {
+	svc := New(ethserver.Config{})
+
+	var reply bool
+	if err := svc.Listening(nil, &ethserver.Empty{}, &reply); err != nil {
+		t.Fatalf("Listening returned an error: %s", err)
+	}
+	if !reply {
+		t.Fatal("expected Listening to report true")
+	}
+}
+
+func TestPeerCountIsAlwaysZero(t *testing.T) {
+	svc := New(ethserver.Config{})
+
+	var reply string
+	if err := svc.PeerCount(nil, &ethserver.Empty{}, &reply); err != nil {
+		t.Fatalf("PeerCount returned an error: %s", err)
+	}
+	if reply != "0x0" {
+		t.Fatalf("expected peer count %q, got %q", "0x0", reply)
+	}
+}
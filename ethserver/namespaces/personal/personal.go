@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package personal implements the `personal_*` JSON-RPC namespace.
+package personal
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+)
+
+// Service implements the personal namespace by querying evmscc for the
+// address derived from the calling identity's MSP certificate.
+type Service struct {
+	sdk       *fabsdk.FabricSDK
+	user      string
+	channelID string
+	evmscc    string
+}
+
+func New(sdk *fabsdk.FabricSDK, user string, cfg ethserver.Config) *Service {
+	return &Service{sdk: sdk, user: user, channelID: cfg.ChannelID, evmscc: cfg.EVMSCC}
+}
+
+func (s *Service) Namespace() string { return "personal" }
+
+func (s *Service) ListAccounts(r *http.Request, _ *ethserver.Empty, reply *[]string) error {
+	fmt.Println("Recieved a request for personal_listAccounts")
+
+	chClient, err := s.sdk.NewChannelClient(s.channelID, s.user)
+	if err != nil {
+		return ethserver.ClientError(err)
+	}
+	defer chClient.Close()
+
+	value, err := ethserver.Query(chClient, s.evmscc, "account", [][]byte{})
+	if err != nil {
+		return err
+	}
+
+	*reply = []string{"0x" + strings.ToLower(string(value))}
+
+	return nil
+}
+
+// NewAccount is not supported: an account's address is derived from the
+// enrollment certificate of the identity submitting transactions, not
+// generated by the bridge, so there is nothing for this RPC to create.
+func (s *Service) NewAccount(r *http.Request, _ *ethserver.DataParam, reply *string) error {
+	return ethserver.NotSupportedError("personal_newAccount", "accounts are derived from the calling identity's MSP certificate")
+}
@@ -0,0 +1,38 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package web3 implements the `web3_*` JSON-RPC namespace.
+package web3
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+)
+
+const ClientVersion = "fabric-chaincode-evm/v0.1.0"
+
+type Service struct{}
+
+func New() *Service { return &Service{} }
+
+func (s *Service) Namespace() string { return "web3" }
+
+func (s *Service) ClientVersion(r *http.Request, _ *ethserver.Empty, reply *string) error {
+	*reply = ClientVersion
+	return nil
+}
+
+func (s *Service) Sha3(r *http.Request, args *ethserver.DataParam, reply *string) error {
+	data, err := hex.DecodeString(ethserver.Strip0xFromHex(string(*args)))
+	if err != nil {
+		return ethserver.InvalidHexError("data", err)
+	}
+
+	*reply = "0x" + hex.EncodeToString(ethserver.Keccak256(data))
+	return nil
+}
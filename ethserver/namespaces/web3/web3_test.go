@@ -0,0 +1,51 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package web3
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+)
+
+func TestClientVersion(t *testing.T) {
+	svc := New()
+
+	var reply string
+	if err := svc.ClientVersion(nil, &ethserver.Empty{}, &reply); err != nil {
+		t.Fatalf("ClientVersion returned an error: %s", err)
+	}
+	if reply != ClientVersion {
+		t.Fatalf("expected client version %q, got %q", ClientVersion, reply)
+	}
+}
+
+func TestSha3(t *testing.T) {
+	svc := New()
+
+	data := ethserver.DataParam("0x")
+	var reply string
+	if err := svc.Sha3(nil, &data, &reply); err != nil {
+		t.Fatalf("Sha3 returned an error: %s", err)
+	}
+
+	// Keccak-256 of the empty byte string, per the well-known test vector.
+	expected := "0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"
+	if reply != expected {
+		t.Fatalf("expected sha3 %q, got %q", expected, reply)
+	}
+}
+
+func TestSha3RejectsInvalidHex(t *testing.T) {
+	svc := New()
+
+	data := ethserver.DataParam("not-hex")
+	var reply string
+	if err := svc.Sha3(nil, &data, &reply); err == nil {
+		t.Fatal("expected an error for non-hex input")
+	}
+}
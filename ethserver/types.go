@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ethserver
+
+// DataParam is a single hex-encoded string argument, e.g. an address or a
+// transaction hash, as sent by JSON-RPC clients.
+type DataParam string
+
+// Empty is used as the argument type for JSON-RPC methods that take no
+// parameters (net/rpc requires a concrete pointer type even when unused).
+type Empty struct{}
+
+type Params struct {
+	From     string
+	To       string
+	Gas      string
+	GasPrice string
+	Value    string
+	Data     string
+	Nonce    string
+}
+
+type TxReceipt struct {
+	TransactionHash   string `json:"transactionHash"`
+	BlockHash         string `json:"blockHash"`
+	BlockNumber       string `json:"blockNumber"`
+	TransactionIndex  string `json:"transactionIndex"`
+	ContractAddress   string `json:"contractAddress"`
+	From              string `json:"from"`
+	To                string `json:"to"`
+	Logs              []Log  `json:"logs"`
+	LogsBloom         string `json:"logsBloom"`
+	Status            string `json:"status"`
+	GasUsed           int    `json:"gasUsed"`
+	CumulativeGasUsed int    `json:"cumulativeGasUsed"`
+}
+
+// Transaction is the standard Ethereum transaction shape returned by
+// eth_getTransactionByHash, reconstructed from the ChaincodeInvocationSpec
+// evmscc was invoked with.
+type Transaction struct {
+	Hash             string `json:"hash"`
+	Nonce            string `json:"nonce"`
+	BlockHash        string `json:"blockHash"`
+	BlockNumber      string `json:"blockNumber"`
+	TransactionIndex string `json:"transactionIndex"`
+	From             string `json:"from"`
+	To               string `json:"to"`
+	Value            string `json:"value"`
+	Input            string `json:"input"`
+}
+
+// Header is a synthetic Ethereum block header derived from a committed
+// Fabric block: Number/Hash come from the block's sequence
+// number and header hash, there being no equivalent of an Ethereum
+// parent-selection or difficulty scheme.
+type Header struct {
+	Number     string `json:"number"`
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parentHash"`
+}
+
+// Log is an EVM log entry reconstructed from a chaincode event emitted
+// by evmscc.
+type Log struct {
+	Address          string   `json:"address"`
+	Topics           []string `json:"topics"`
+	Data             string   `json:"data"`
+	BlockNumber      string   `json:"blockNumber"`
+	BlockHash        string   `json:"blockHash"`
+	TransactionHash  string   `json:"transactionHash"`
+	TransactionIndex string   `json:"transactionIndex"`
+	LogIndex         string   `json:"logIndex"`
+	Removed          bool     `json:"removed"`
+}
+
+// Block is the standard Ethereum block shape returned by
+// eth_getBlockByNumber/eth_getBlockByHash. Only the fields this bridge can
+// derive from a Fabric block are populated; there is no gas limit,
+// difficulty or miner to report.
+type Block struct {
+	Number       string   `json:"number"`
+	Hash         string   `json:"hash"`
+	ParentHash   string   `json:"parentHash"`
+	Transactions []string `json:"transactions"`
+}
+
+var ZeroAddress = make([]byte, 20)
@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ethserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/api/apitxn"
+	"github.com/hyperledger/fabric/protos/peer"
+	"golang.org/x/crypto/sha3"
+)
+
+func Query(chClient apitxn.ChannelClient, chaincodeID string, function string, queryArgs [][]byte) ([]byte, error) {
+	value, err := chClient.Query(apitxn.QueryRequest{
+		ChaincodeID: chaincodeID,
+		Fcn:         function,
+		Args:        queryArgs,
+	})
+	if err != nil {
+		return nil, ClassifyChaincodeError(chaincodeID, err)
+	}
+	return value, nil
+}
+
+func Strip0xFromHex(addr string) string {
+	stripped := strings.Split(addr, "0x")
+	return stripped[len(stripped)-1]
+}
+
+func GetPayloads(txActions *peer.TransactionAction) (*peer.ChaincodeProposalPayload, *peer.ChaincodeAction, error) {
+	// TODO: pass in the tx type (in what follows we're assuming the type is ENDORSER_TRANSACTION)
+	ccPayload := &peer.ChaincodeActionPayload{}
+	err := proto.Unmarshal(txActions.Payload, ccPayload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ccPayload.Action == nil || ccPayload.Action.ProposalResponsePayload == nil {
+		return nil, nil, fmt.Errorf("no payload in ChaincodeActionPayload")
+	}
+
+	ccProposalPayload := &peer.ChaincodeProposalPayload{}
+	err = proto.Unmarshal(ccPayload.ChaincodeProposalPayload, ccProposalPayload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pRespPayload := &peer.ProposalResponsePayload{}
+	err = proto.Unmarshal(ccPayload.Action.ProposalResponsePayload, pRespPayload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if pRespPayload.Extension == nil {
+		return nil, nil, fmt.Errorf("response payload is missing extension")
+	}
+
+	respPayload := &peer.ChaincodeAction{}
+	err = proto.Unmarshal(pRespPayload.Extension, respPayload)
+	if err != nil {
+		return ccProposalPayload, nil, err
+	}
+	return ccProposalPayload, respPayload, nil
+}
+
+// Keccak256 hashes data with the Keccak-256 variant used throughout
+// Ethereum (i.e. pre-standardization SHA-3), which both address
+// derivation and web3_sha3 rely on.
+func Keccak256(data []byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+// DecodeEVMLogs decodes evmscc's chaincode-event payload into the logs
+// it emitted. evmscc emits its logs as a JSON-encoded []Log in the
+// chaincode event payload.
+func DecodeEVMLogs(eventBytes []byte) ([]Log, error) {
+	if len(eventBytes) == 0 {
+		return nil, nil
+	}
+
+	ccEvent := &peer.ChaincodeEvent{}
+	if err := proto.Unmarshal(eventBytes, ccEvent); err != nil {
+		return nil, err
+	}
+
+	if len(ccEvent.GetPayload()) == 0 {
+		return nil, nil
+	}
+
+	var logs []Log
+	if err := json.Unmarshal(ccEvent.GetPayload(), &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
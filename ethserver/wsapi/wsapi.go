@@ -0,0 +1,206 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package wsapi provides the WebSocket half of the JSON-RPC surface:
+// eth_subscribe/eth_unsubscribe, which need a persistent connection to
+// push notifications and so can't be served by the unary HTTP codec in
+// ethserver.Server.
+package wsapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver"
+	"github.com/hyperledger/fabric-chaincode-evm/ethserver/blockfeed"
+)
+
+type request struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params []interface{}   `json:"params"`
+}
+
+type response struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+type notification struct {
+	Method string           `json:"method"`
+	Params notificationBody `json:"params"`
+}
+
+type notificationBody struct {
+	Subscription string      `json:"subscription"`
+	Result       interface{} `json:"result"`
+}
+
+// Handler upgrades incoming HTTP requests to WebSocket connections and
+// services eth_subscribe/eth_unsubscribe over them.
+type Handler struct {
+	feed     *blockfeed.Feed
+	upgrader websocket.Upgrader
+}
+
+func NewHandler(feed *blockfeed.Feed) *Handler {
+	return &Handler{feed: feed}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println("failed to upgrade websocket connection:", err)
+		return
+	}
+	defer conn.Close()
+
+	c := newClient(conn, h.feed)
+	c.serve()
+}
+
+type client struct {
+	conn *websocket.Conn
+	feed *blockfeed.Feed
+
+	unsub  map[string]func()
+	nextID uint64
+	out    chan notification
+	done   chan struct{}
+}
+
+func newClient(conn *websocket.Conn, feed *blockfeed.Feed) *client {
+	return &client{
+		conn:  conn,
+		feed:  feed,
+		unsub: map[string]func(){},
+		out:   make(chan notification, 64),
+		done:  make(chan struct{}),
+	}
+}
+
+func (c *client) serve() {
+	go c.writeLoop()
+	defer close(c.done)
+	defer c.unsubscribeAll()
+
+	for {
+		var req request
+		if err := c.conn.ReadJSON(&req); err != nil {
+			return
+		}
+
+		resp := c.handle(req)
+		if err := c.conn.WriteJSON(resp); err != nil {
+			return
+		}
+	}
+}
+
+func (c *client) writeLoop() {
+	for {
+		select {
+		case n := <-c.out:
+			if err := c.conn.WriteJSON(n); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *client) handle(req request) response {
+	switch req.Method {
+	case "eth_subscribe":
+		return c.subscribe(req)
+	case "eth_unsubscribe":
+		return c.unsubscribe(req)
+	default:
+		return response{ID: req.ID, Error: fmt.Sprintf("method %s is not supported over websocket; use the HTTP JSON-RPC endpoint", req.Method)}
+	}
+}
+
+func (c *client) subscribe(req request) response {
+	if len(req.Params) == 0 {
+		return response{ID: req.ID, Error: "eth_subscribe requires a subscription type"}
+	}
+	kind, _ := req.Params[0].(string)
+
+	id := fmt.Sprintf("0x%x", c.nextID)
+	c.nextID++
+
+	switch kind {
+	case "newHeads":
+		ch, cancel := c.feed.SubscribeHeads()
+		c.unsub[id] = cancel
+		go c.forwardHeads(id, ch)
+	case "logs":
+		ch, cancel := c.feed.SubscribeLogs()
+		c.unsub[id] = cancel
+		go c.forwardLogs(id, ch)
+	case "newPendingTransactions":
+		ch, cancel := c.feed.SubscribePendingTransactions()
+		c.unsub[id] = cancel
+		go c.forwardPending(id, ch)
+	default:
+		return response{ID: req.ID, Error: fmt.Sprintf("unsupported subscription type: %s", kind)}
+	}
+
+	return response{ID: req.ID, Result: id}
+}
+
+func (c *client) unsubscribe(req request) response {
+	if len(req.Params) == 0 {
+		return response{ID: req.ID, Error: "eth_unsubscribe requires a subscription id"}
+	}
+	id, _ := req.Params[0].(string)
+
+	cancel, ok := c.unsub[id]
+	if !ok {
+		return response{ID: req.ID, Result: false}
+	}
+	cancel()
+	delete(c.unsub, id)
+
+	return response{ID: req.ID, Result: true}
+}
+
+func (c *client) unsubscribeAll() {
+	for _, cancel := range c.unsub {
+		cancel()
+	}
+}
+
+func (c *client) forwardHeads(id string, ch <-chan ethserver.Header) {
+	for head := range ch {
+		c.send(id, head)
+	}
+}
+
+func (c *client) forwardLogs(id string, ch <-chan ethserver.Log) {
+	for l := range ch {
+		c.send(id, l)
+	}
+}
+
+func (c *client) forwardPending(id string, ch <-chan string) {
+	for txHash := range ch {
+		c.send(id, txHash)
+	}
+}
+
+func (c *client) send(id string, result interface{}) {
+	select {
+	case c.out <- notification{Method: "eth_subscription", Params: notificationBody{Subscription: id, Result: result}}:
+	case <-c.done:
+	}
+}